@@ -0,0 +1,76 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CompanionFile is a candidate-unwanted file that was spared because a
+// same-basename media file exists alongside it (e.g. Movie.2020.nfo
+// next to Movie.2020.mkv) — Plex actually uses these for posters,
+// fanart, and subtitle metadata.
+type CompanionFile struct {
+	FileInfo
+	MediaPath string
+}
+
+// companionBase returns the part of filename before its extension, used
+// to pair a metadata/subtitle file with its media file.
+func companionBase(filename string) string {
+	return strings.TrimSuffix(filename, filepath.Ext(filename))
+}
+
+// DetectCompanions scans candidates for files that share a directory
+// and base name with a file whose extension is in safeExtensions, and
+// splits them out as protected companions instead of unwanted files.
+// Pairing decisions are logged so a user can see why a file was spared.
+func DetectCompanions(candidates []FileInfo, safeExtensions []string) (companions []CompanionFile, rest []FileInfo) {
+	dirEntries := make(map[string][]os.DirEntry)
+
+	for _, file := range candidates {
+		dir := filepath.Dir(file.Path)
+
+		entries, ok := dirEntries[dir]
+		if !ok {
+			entries, _ = os.ReadDir(dir)
+			dirEntries[dir] = entries
+		}
+
+		base := companionBase(filepath.Base(file.Path))
+		if mediaPath := findMediaSibling(entries, dir, base, safeExtensions); mediaPath != "" {
+			companions = append(companions, CompanionFile{FileInfo: file, MediaPath: mediaPath})
+			log.Printf("companion_kept path=%q media=%q\n", file.Path, mediaPath)
+			continue
+		}
+
+		rest = append(rest, file)
+	}
+
+	return companions, rest
+}
+
+func findMediaSibling(entries []os.DirEntry, dir, base string, safeExtensions []string) string {
+	lowerBase := strings.ToLower(base)
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name := entry.Name()
+		if strings.ToLower(companionBase(name)) != lowerBase {
+			continue
+		}
+
+		ext := strings.ToLower(filepath.Ext(name))
+		for _, safeExt := range safeExtensions {
+			if ext == strings.ToLower(safeExt) {
+				return filepath.Join(dir, name)
+			}
+		}
+	}
+
+	return ""
+}
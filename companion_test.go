@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectCompanionsKeepsMatchingMedia(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "Movie.2020.mkv"), []byte("media"))
+	writeFile(t, filepath.Join(dir, "Movie.2020.nfo"), []byte("metadata"))
+
+	candidates := []FileInfo{{Path: filepath.Join(dir, "Movie.2020.nfo"), Size: 8}}
+
+	companions, rest := DetectCompanions(candidates, []string{".mkv"})
+
+	if len(rest) != 0 {
+		t.Errorf("expected no files left in rest, got %v", rest)
+	}
+	if len(companions) != 1 || companions[0].MediaPath != filepath.Join(dir, "Movie.2020.mkv") {
+		t.Errorf("expected Movie.2020.nfo to be kept as a companion of Movie.2020.mkv, got %+v", companions)
+	}
+}
+
+func TestDetectCompanionsWithoutMediaStaysUnwanted(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "Orphan.nfo"), []byte("metadata"))
+
+	candidates := []FileInfo{{Path: filepath.Join(dir, "Orphan.nfo"), Size: 8}}
+
+	companions, rest := DetectCompanions(candidates, []string{".mkv"})
+
+	if len(companions) != 0 {
+		t.Errorf("expected no companions, got %+v", companions)
+	}
+	if len(rest) != 1 {
+		t.Errorf("expected Orphan.nfo to remain unwanted, got %v", rest)
+	}
+}
+
+func TestCompanionBaseStripsExtension(t *testing.T) {
+	if got := companionBase("Movie.2020.mkv"); got != "Movie.2020" {
+		t.Errorf("companionBase(%q) = %q, want %q", "Movie.2020.mkv", got, "Movie.2020")
+	}
+}
+
+func TestFindMediaSiblingRequiresSameDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "Movie.2020.mkv"), []byte("media"))
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := findMediaSibling(entries, dir, "Movie.2020", []string{".mkv"}); got == "" {
+		t.Error("expected to find Movie.2020.mkv as a media sibling")
+	}
+	if got := findMediaSibling(entries, dir, "OtherMovie", []string{".mkv"}); got != "" {
+		t.Errorf("expected no media sibling for a different base name, got %q", got)
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// DeleteMode selects how the Deleter disposes of a queued file.
+type DeleteMode int
+
+const (
+	DeleteModePermanent DeleteMode = iota
+	DeleteModeRecycleBin
+	DeleteModeQuarantine
+)
+
+// deleteJob is a single file queued for removal, along with the retry
+// count so far.
+type deleteJob struct {
+	file    FileInfo
+	mode    DeleteMode
+	attempt int
+	done    chan error
+}
+
+// Deleter removes files on a background queue so that deletions which
+// fail because a file is still open (common on Windows right after Plex
+// stops streaming it) get retried with backoff instead of failing the
+// whole batch.
+type Deleter struct {
+	QuarantineDir string
+	QuarantineAge time.Duration
+	MaxRetries    int
+	RetryBackoff  time.Duration
+
+	OnProgress func(deletedCount int, deletedSize int64, path string, err error)
+
+	jobs chan deleteJob
+	quit chan struct{}
+	wg   sync.WaitGroup
+
+	mu      sync.Mutex
+	deleted int
+	freed   int64
+}
+
+// NewDeleter creates a Deleter and starts its background worker.
+func NewDeleter() *Deleter {
+	d := &Deleter{
+		MaxRetries:   5,
+		RetryBackoff: 2 * time.Second,
+		jobs:         make(chan deleteJob, 256),
+		quit:         make(chan struct{}),
+	}
+	d.wg.Add(1)
+	go d.run()
+	return d
+}
+
+func (d *Deleter) run() {
+	defer d.wg.Done()
+	for {
+		select {
+		case job, ok := <-d.jobs:
+			if !ok {
+				return
+			}
+			d.process(job)
+		case <-d.quit:
+			d.drain()
+			return
+		}
+	}
+}
+
+// drain processes every job already queued in d.jobs so that a pending
+// Close() doesn't abandon deletes that were enqueued before shutdown
+// started. It never blocks waiting for new jobs.
+func (d *Deleter) drain() {
+	for {
+		select {
+		case job, ok := <-d.jobs:
+			if !ok {
+				return
+			}
+			d.process(job)
+		default:
+			return
+		}
+	}
+}
+
+func (d *Deleter) process(job deleteJob) {
+	err := d.remove(job.file.Path, job.mode)
+	if err != nil && job.attempt < d.MaxRetries && isRetryableDeleteErr(err) {
+		job.attempt++
+		backoff := d.RetryBackoff * time.Duration(job.attempt)
+		log.Printf("Delete of %s failed (%v), retrying in %s (attempt %d/%d)\n",
+			job.file.Path, err, backoff, job.attempt, d.MaxRetries)
+		abandon := func() {
+			if job.done != nil {
+				job.done <- fmt.Errorf("deleter shut down before retrying %s: %w", job.file.Path, err)
+			}
+		}
+		time.AfterFunc(backoff, func() {
+			// Check quit first: select picks at random when both cases
+			// are ready, and a retry queued after shutdown is never run.
+			select {
+			case <-d.quit:
+				abandon()
+				return
+			default:
+			}
+			select {
+			case d.jobs <- job:
+			case <-d.quit:
+				abandon()
+			}
+		})
+		return
+	}
+
+	if err != nil {
+		log.Printf("Error deleting %s: %v\n", job.file.Path, err)
+		if job.done != nil {
+			job.done <- err
+		}
+		if d.OnProgress != nil {
+			d.OnProgress(0, 0, job.file.Path, err)
+		}
+		return
+	}
+
+	d.mu.Lock()
+	d.deleted++
+	d.freed += job.file.Size
+	deleted, freed := d.deleted, d.freed
+	d.mu.Unlock()
+
+	log.Printf("Deleted: %s (%s)\n", job.file.Path, formatSize(job.file.Size))
+	if job.done != nil {
+		job.done <- nil
+	}
+	if d.OnProgress != nil {
+		d.OnProgress(deleted, freed, job.file.Path, nil)
+	}
+}
+
+func (d *Deleter) remove(path string, mode DeleteMode) error {
+	switch mode {
+	case DeleteModeRecycleBin:
+		return moveToRecycleBin(path)
+	case DeleteModeQuarantine:
+		return d.moveToQuarantine(path)
+	default:
+		return os.Remove(path)
+	}
+}
+
+func (d *Deleter) moveToQuarantine(path string) error {
+	if d.QuarantineDir == "" {
+		return fmt.Errorf("quarantine directory not configured")
+	}
+	if err := os.MkdirAll(d.QuarantineDir, 0o755); err != nil {
+		return err
+	}
+	dest := filepath.Join(d.QuarantineDir, fmt.Sprintf("%d_%s", time.Now().UnixNano(), filepath.Base(path)))
+	return os.Rename(path, dest)
+}
+
+// Enqueue queues file for deletion under mode and blocks until the
+// deletion (including any retries) completes or is abandoned. It is
+// safe to call from multiple goroutines.
+func (d *Deleter) Enqueue(file FileInfo, mode DeleteMode) error {
+	done := make(chan error, 1)
+	select {
+	case <-d.quit:
+		return fmt.Errorf("deleter is shutting down")
+	default:
+	}
+	select {
+	case d.jobs <- deleteJob{file: file, mode: mode, done: done}:
+	case <-d.quit:
+		return fmt.Errorf("deleter is shutting down")
+	}
+	return <-done
+}
+
+// Close stops accepting new jobs, processes the jobs already queued and
+// waits for the worker to exit. A retry still waiting out its backoff is
+// abandoned when its timer fires, and its Enqueue call returns an error.
+func (d *Deleter) Close() {
+	close(d.quit)
+	d.wg.Wait()
+}
+
+// StartQuarantinePurge runs in the background, permanently removing
+// quarantined files older than QuarantineAge once per interval. It
+// returns a stop function that halts the purge loop.
+func (d *Deleter) StartQuarantinePurge(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				d.purgeAgedQuarantine()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+func (d *Deleter) purgeAgedQuarantine() {
+	if d.QuarantineDir == "" || d.QuarantineAge <= 0 {
+		return
+	}
+
+	entries, err := os.ReadDir(d.QuarantineDir)
+	if err != nil {
+		log.Printf("Error reading quarantine dir %s: %v\n", d.QuarantineDir, err)
+		return
+	}
+
+	cutoff := time.Now().Add(-d.QuarantineAge)
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+
+		path := filepath.Join(d.QuarantineDir, entry.Name())
+		if err := os.Remove(path); err != nil {
+			log.Printf("Error purging aged quarantine file %s: %v\n", path, err)
+			continue
+		}
+		log.Printf("Purged aged quarantine file: %s\n", path)
+	}
+}
+
+// isRetryableDeleteErr reports whether err looks like Plex is still
+// holding the file open rather than a permanent failure. The common
+// case on Windows is ERROR_SHARING_VIOLATION, which Go's os/syscall
+// layer does NOT classify as a permission error, so it's checked
+// separately from os.IsPermission.
+func isRetryableDeleteErr(err error) bool {
+	if os.IsPermission(err) {
+		return true
+	}
+	return errors.Is(err, windows.ERROR_SHARING_VIOLATION) || errors.Is(err, windows.ERROR_LOCK_VIOLATION)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+func TestIsRetryableDeleteErr(t *testing.T) {
+	if !isRetryableDeleteErr(fmt.Errorf("open: %w", windows.ERROR_SHARING_VIOLATION)) {
+		t.Error("expected ERROR_SHARING_VIOLATION to be retryable")
+	}
+	if !isRetryableDeleteErr(fmt.Errorf("open: %w", windows.ERROR_LOCK_VIOLATION)) {
+		t.Error("expected ERROR_LOCK_VIOLATION to be retryable")
+	}
+	if isRetryableDeleteErr(fmt.Errorf("no such file or directory")) {
+		t.Error("expected an unrelated error not to be retryable")
+	}
+}
+
+func TestDeleterDrainsQueuedJobsOnClose(t *testing.T) {
+	dir := t.TempDir()
+	d := NewDeleter()
+
+	const n = 50
+	dones := make([]chan error, n)
+	for i := 0; i < n; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("file%d.txt", i))
+		writeFile(t, path, []byte("x"))
+
+		done := make(chan error, 1)
+		dones[i] = done
+		d.jobs <- deleteJob{file: FileInfo{Path: path, Size: 1}, mode: DeleteModePermanent, done: done}
+	}
+
+	d.Close()
+
+	for i, done := range dones {
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("job %d failed: %v", i, err)
+			}
+		default:
+			t.Errorf("job %d was abandoned instead of drained before Close returned", i)
+		}
+	}
+}
+
+func TestEnqueueAfterCloseReturnsErrorInsteadOfBlocking(t *testing.T) {
+	d := NewDeleter()
+	d.Close()
+
+	result := make(chan error, 1)
+	go func() {
+		result <- d.Enqueue(FileInfo{Path: filepath.Join(t.TempDir(), "whatever.txt"), Size: 1}, DeleteModePermanent)
+	}()
+
+	select {
+	case err := <-result:
+		if err == nil {
+			t.Error("expected Enqueue after Close to return an error, got nil")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Enqueue blocked instead of returning after Close")
+	}
+}
+
+func TestPurgeAgedQuarantineRemovesOnlyOldFiles(t *testing.T) {
+	dir := t.TempDir()
+	d := &Deleter{QuarantineDir: dir, QuarantineAge: 24 * time.Hour}
+
+	oldPath := filepath.Join(dir, "old.txt")
+	newPath := filepath.Join(dir, "new.txt")
+	writeFile(t, oldPath, []byte("x"))
+	writeFile(t, newPath, []byte("x"))
+
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(oldPath, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	d.purgeAgedQuarantine()
+
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Errorf("expected old.txt to be purged, stat err = %v", err)
+	}
+	if _, err := os.Stat(newPath); err != nil {
+		t.Errorf("expected new.txt to survive the purge, got %v", err)
+	}
+}
+
+func TestPurgeAgedQuarantineNoopWithoutConfig(t *testing.T) {
+	d := &Deleter{}
+	// Must not panic or touch the filesystem when quarantine isn't configured.
+	d.purgeAgedQuarantine()
+}
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lxn/walk"
+)
+
+// CheckState is the tri-state checkbox value for a preview tree node: a
+// directory is PartiallyChecked when some but not all of its
+// descendants are checked.
+type CheckState int
+
+const (
+	Unchecked CheckState = iota
+	PartiallyChecked
+	Checked
+)
+
+// FileTreeNode is a single file or directory in the dry-run preview
+// tree. It implements walk.TreeItem.
+type FileTreeNode struct {
+	Name     string
+	FullPath string
+	IsDir    bool
+	Size     int64
+	State    CheckState
+
+	parent         *FileTreeNode
+	children       []*FileTreeNode
+	defaultChecked bool // leaf's checked state before cache overrides or user edits
+}
+
+func (n *FileTreeNode) Text() string {
+	box := "[ ]"
+	switch n.State {
+	case Checked:
+		box = "[x]"
+	case PartiallyChecked:
+		box = "[~]"
+	}
+
+	if n.IsDir {
+		return fmt.Sprintf("%s %s/", box, n.Name)
+	}
+	return fmt.Sprintf("%s %s (%s)", box, n.Name, formatSize(n.Size))
+}
+
+func (n *FileTreeNode) Parent() walk.TreeItem {
+	if n.parent == nil {
+		return nil
+	}
+	return n.parent
+}
+
+func (n *FileTreeNode) ChildCount() int {
+	return len(n.children)
+}
+
+func (n *FileTreeNode) ChildAt(index int) walk.TreeItem {
+	return n.children[index]
+}
+
+// FileTreeModel implements walk.TreeModel over a forest of FileTreeNode
+// roots, mirroring the directory hierarchy of the scanned candidates.
+type FileTreeModel struct {
+	walk.TreeModelBase
+	roots []*FileTreeNode
+}
+
+func (m *FileTreeModel) LazyPopulation() bool {
+	return false
+}
+
+func (m *FileTreeModel) RootCount() int {
+	return len(m.roots)
+}
+
+func (m *FileTreeModel) RootAt(index int) walk.TreeItem {
+	return m.roots[index]
+}
+
+// BuildFileTree arranges candidates into a directory tree rooted at
+// scanRoot. Each file defaults to Checked, unless it's in
+// defaultUnchecked (e.g. an unverified archive part) or cache has a
+// stored override for its path, mirroring how syncthing remembers
+// per-file overrides across re-scans of the same folder.
+func BuildFileTree(scanRoot string, candidates []FileInfo, defaultUnchecked map[string]bool, cache *SelectionCache) *FileTreeModel {
+	dirs := map[string]*FileTreeNode{scanRoot: {Name: filepath.Base(scanRoot), FullPath: scanRoot, IsDir: true}}
+
+	for _, file := range candidates {
+		parent := dirNodeFor(dirs, filepath.Dir(file.Path), scanRoot)
+
+		checked := !defaultUnchecked[file.Path]
+		if override, ok := cache.Paths[file.Path]; ok {
+			checked = override
+		}
+
+		state := Unchecked
+		if checked {
+			state = Checked
+		}
+
+		leaf := &FileTreeNode{
+			Name:           filepath.Base(file.Path),
+			FullPath:       file.Path,
+			Size:           file.Size,
+			State:          state,
+			parent:         parent,
+			defaultChecked: !defaultUnchecked[file.Path],
+		}
+		parent.children = append(parent.children, leaf)
+	}
+
+	root := dirs[scanRoot]
+	sortTree(root)
+	recomputeState(root)
+
+	return &FileTreeModel{roots: []*FileTreeNode{root}}
+}
+
+// dirNodeFor returns the directory node for path, creating it (and any
+// missing ancestors up to scanRoot) on demand.
+func dirNodeFor(dirs map[string]*FileTreeNode, path, scanRoot string) *FileTreeNode {
+	if node, ok := dirs[path]; ok {
+		return node
+	}
+
+	parent := dirNodeFor(dirs, filepath.Dir(path), scanRoot)
+	node := &FileTreeNode{Name: filepath.Base(path), FullPath: path, IsDir: true, parent: parent}
+	parent.children = append(parent.children, node)
+	dirs[path] = node
+	return node
+}
+
+func sortTree(node *FileTreeNode) {
+	sort.Slice(node.children, func(i, j int) bool {
+		a, b := node.children[i], node.children[j]
+		if a.IsDir != b.IsDir {
+			return a.IsDir
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+	for _, child := range node.children {
+		if child.IsDir {
+			sortTree(child)
+		}
+	}
+}
+
+// recomputeState sets every directory's State from its children,
+// post-order, so a folder shows Checked/Unchecked/PartiallyChecked
+// based on what's actually selected beneath it.
+func recomputeState(node *FileTreeNode) CheckState {
+	if !node.IsDir {
+		return node.State
+	}
+
+	allChecked, allUnchecked := true, true
+	for _, child := range node.children {
+		switch recomputeState(child) {
+		case Checked:
+			allUnchecked = false
+		case Unchecked:
+			allChecked = false
+		case PartiallyChecked:
+			allChecked, allUnchecked = false, false
+		}
+	}
+
+	switch {
+	case len(node.children) == 0:
+		node.State = Unchecked
+	case allChecked:
+		node.State = Checked
+	case allUnchecked:
+		node.State = Unchecked
+	default:
+		node.State = PartiallyChecked
+	}
+
+	return node.State
+}
+
+// ToggleNode flips the node at target's path within root (and, if it's
+// a directory, every descendant) between Checked and Unchecked, then
+// fixes up ancestor tri-state. target may be a node from a filtered
+// clone of root rather than root itself (FilterTree clones nodes for
+// display), so the canonical node is re-resolved by path first; that
+// keeps the toggle applied to the real model that CollectChecked/
+// CollectOverrides read from, rather than a throwaway clone.
+func ToggleNode(root *FileTreeNode, target *FileTreeNode) {
+	node := findNode(root, target.FullPath, target.IsDir)
+	if node == nil {
+		return
+	}
+
+	next := Checked
+	if node.State == Checked {
+		next = Unchecked
+	}
+	setStateRecursive(node, next)
+	recomputeState(root)
+}
+
+func findNode(node *FileTreeNode, path string, isDir bool) *FileTreeNode {
+	if node.FullPath == path && node.IsDir == isDir {
+		return node
+	}
+	if !node.IsDir {
+		return nil
+	}
+	for _, child := range node.children {
+		if found := findNode(child, path, isDir); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func setStateRecursive(node *FileTreeNode, state CheckState) {
+	node.State = state
+	for _, child := range node.children {
+		setStateRecursive(child, state)
+	}
+}
+
+// CollectChecked walks the tree and returns every Checked leaf file as
+// a FileInfo, ready for deletion.
+func CollectChecked(model *FileTreeModel) []FileInfo {
+	var files []FileInfo
+	for _, root := range model.roots {
+		collectChecked(root, &files)
+	}
+	return files
+}
+
+func collectChecked(node *FileTreeNode, out *[]FileInfo) {
+	if !node.IsDir {
+		if node.State == Checked {
+			*out = append(*out, FileInfo{Path: node.FullPath, Size: node.Size})
+		}
+		return
+	}
+	for _, child := range node.children {
+		collectChecked(child, out)
+	}
+}
+
+// CollectOverrides records every leaf file whose checked state differs
+// from its own scan-time default, so SelectionCache only needs to
+// persist deviations (e.g. a user re-checking an unverified archive
+// part that defaulted to unchecked) instead of assuming the default is
+// always "everything checked".
+func CollectOverrides(model *FileTreeModel) map[string]bool {
+	overrides := make(map[string]bool)
+	for _, root := range model.roots {
+		collectOverrides(root, overrides)
+	}
+	return overrides
+}
+
+func collectOverrides(node *FileTreeNode, out map[string]bool) {
+	if !node.IsDir {
+		checked := node.State == Checked
+		if checked != node.defaultChecked {
+			out[node.FullPath] = checked
+		}
+		return
+	}
+	for _, child := range node.children {
+		collectOverrides(child, out)
+	}
+}
+
+// FilterTree returns a copy of model containing only leaves whose name
+// contains query (case-insensitive), along with the directories needed
+// to reach them. An empty query returns model unchanged.
+func FilterTree(model *FileTreeModel, query string) *FileTreeModel {
+	if query == "" {
+		return model
+	}
+
+	query = strings.ToLower(query)
+	var roots []*FileTreeNode
+	for _, root := range model.roots {
+		if filtered := filterNode(root, query); filtered != nil {
+			roots = append(roots, filtered)
+		}
+	}
+	return &FileTreeModel{roots: roots}
+}
+
+func filterNode(node *FileTreeNode, query string) *FileTreeNode {
+	if !node.IsDir {
+		if strings.Contains(strings.ToLower(node.Name), query) {
+			return &FileTreeNode{
+				Name:           node.Name,
+				FullPath:       node.FullPath,
+				Size:           node.Size,
+				State:          node.State,
+				defaultChecked: node.defaultChecked,
+			}
+		}
+		return nil
+	}
+
+	clone := &FileTreeNode{Name: node.Name, FullPath: node.FullPath, IsDir: true, State: node.State}
+	for _, child := range node.children {
+		if filtered := filterNode(child, query); filtered != nil {
+			filtered.parent = clone
+			clone.children = append(clone.children, filtered)
+		}
+	}
+
+	if len(clone.children) == 0 {
+		return nil
+	}
+	return clone
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func newTestCache() *SelectionCache {
+	return &SelectionCache{Root: "/root", Paths: map[string]bool{}}
+}
+
+func TestBuildFileTreeDefaultUnchecked(t *testing.T) {
+	candidates := []FileInfo{
+		{Path: "/root/Movies/Movie.2020.rar", Size: 100},
+		{Path: "/root/Movies/Movie.2020.nfo", Size: 10},
+	}
+	defaultUnchecked := map[string]bool{"/root/Movies/Movie.2020.rar": true}
+
+	model := BuildFileTree(filepath.FromSlash("/root"), candidates, defaultUnchecked, newTestCache())
+
+	rar := findNode(model.roots[0], "/root/Movies/Movie.2020.rar", false)
+	if rar == nil || rar.State != Unchecked {
+		t.Fatalf("expected unverified archive part to default to Unchecked, got %+v", rar)
+	}
+
+	nfo := findNode(model.roots[0], "/root/Movies/Movie.2020.nfo", false)
+	if nfo == nil || nfo.State != Checked {
+		t.Fatalf("expected non-archive file to default to Checked, got %+v", nfo)
+	}
+}
+
+func TestBuildFileTreeCacheOverride(t *testing.T) {
+	candidates := []FileInfo{{Path: "/root/Movie.mkv", Size: 100}}
+	cache := &SelectionCache{Root: "/root", Paths: map[string]bool{"/root/Movie.mkv": false}}
+
+	model := BuildFileTree(filepath.FromSlash("/root"), candidates, nil, cache)
+
+	node := findNode(model.roots[0], "/root/Movie.mkv", false)
+	if node == nil || node.State != Unchecked {
+		t.Fatalf("expected cached override to unchecked, got %+v", node)
+	}
+}
+
+func TestToggleNodeRecursesIntoDirectory(t *testing.T) {
+	candidates := []FileInfo{
+		{Path: "/root/Movies/a.mkv", Size: 1},
+		{Path: "/root/Movies/b.mkv", Size: 1},
+	}
+	model := BuildFileTree(filepath.FromSlash("/root"), candidates, nil, newTestCache())
+
+	dir := findNode(model.roots[0], filepath.FromSlash("/root/Movies"), true)
+	ToggleNode(model.roots[0], dir)
+
+	for _, path := range []string{"/root/Movies/a.mkv", "/root/Movies/b.mkv"} {
+		if node := findNode(model.roots[0], path, false); node.State != Unchecked {
+			t.Errorf("expected %s to be unchecked after toggling its parent directory, got %v", path, node.State)
+		}
+	}
+}
+
+func TestCollectCheckedSkipsUncheckedLeaves(t *testing.T) {
+	candidates := []FileInfo{
+		{Path: "/root/a.mkv", Size: 1},
+		{Path: "/root/b.rar", Size: 1},
+	}
+	model := BuildFileTree(filepath.FromSlash("/root"), candidates, map[string]bool{"/root/b.rar": true}, newTestCache())
+
+	files := CollectChecked(model)
+	if len(files) != 1 || files[0].Path != "/root/a.mkv" {
+		t.Errorf("expected only a.mkv to be collected, got %v", files)
+	}
+}
+
+func TestCollectOverridesIsRelativeToEachLeafsDefault(t *testing.T) {
+	// b.rar defaults to unchecked (unverified archive); the user
+	// explicitly re-checks it in the preview tree. That's a deviation
+	// from b.rar's own default and must be recorded, even though the
+	// leaf ends up Checked like most files do.
+	candidates := []FileInfo{
+		{Path: "/root/a.mkv", Size: 1},
+		{Path: "/root/b.rar", Size: 1},
+	}
+	model := BuildFileTree(filepath.FromSlash("/root"), candidates, map[string]bool{"/root/b.rar": true}, newTestCache())
+
+	ToggleNode(model.roots[0], findNode(model.roots[0], "/root/b.rar", false))
+
+	overrides := CollectOverrides(model)
+	if checked, ok := overrides["/root/b.rar"]; !ok || !checked {
+		t.Errorf("expected b.rar's user override to be recorded as checked=true, got %v (ok=%v)", checked, ok)
+	}
+	if _, ok := overrides["/root/a.mkv"]; ok {
+		t.Errorf("expected untouched a.mkv to have no recorded override")
+	}
+}
+
+func TestFilterTreeDoesNotCorruptRealTreeParents(t *testing.T) {
+	candidates := []FileInfo{
+		{Path: "/root/Movies/Movie.2020.mkv", Size: 1},
+		{Path: "/root/Movies/Other.mkv", Size: 1},
+	}
+	model := BuildFileTree(filepath.FromSlash("/root"), candidates, nil, newTestCache())
+	realLeaf := findNode(model.roots[0], "/root/Movies/Movie.2020.mkv", false)
+	realParent := realLeaf.Parent()
+
+	filtered := FilterTree(model, "movie")
+	_ = filtered
+
+	if realLeaf.Parent() != realParent {
+		t.Errorf("filtering the tree must not change the real model's node ancestry")
+	}
+}
@@ -6,35 +6,16 @@ import (
 	"log"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/Gooshy/plex_cleanup/plexapi"
 	"github.com/lxn/walk"
 	. "github.com/lxn/walk/declarative"
 )
 
-// File extension definitions
-var (
-	// Extensions to remove (unwanted files)
-	unwantedExtensions = []string{
-		".rar", ".zip", ".7z", ".sfv", ".idx", ".nfo", ".txt",
-		".par", ".par2", ".jpg", ".jpeg", ".png", ".gif",
-	}
-
-	// Safe extensions (media files that should NOT be deleted)
-	safeExtensions = []string{
-		".mp4", ".mkv", ".avi", ".mov", ".wmv", ".m4v", ".mpg", ".mpeg",
-		".flv", ".vob", ".webm", ".divx", ".3gp", ".h264", ".h265",
-	}
-
-	// Patterns for file matching
-	numberedPattern = regexp.MustCompile(`\.\d{3}$`)           // Matches .001, .002, etc.
-	rarPartPattern  = regexp.MustCompile(`-\.r\d{2}$`)         // Matches -.r08, -.r09, etc. (exact format from image)
-	partPattern     = regexp.MustCompile(`\.part\d+$`)         // Matches .part1, .part2, etc.
-)
-
 // FileInfo struct for tracking unwanted files
 type FileInfo struct {
 	Path string
@@ -47,6 +28,16 @@ type ExtStats struct {
 	Size  int64
 }
 
+// ScanError records a single path that the walk could not process
+// (permission denied, unreadable directory, stat failure, etc.) so it
+// can be surfaced in the UI instead of silently dropped or aborting the
+// whole scan.
+type ScanError struct {
+	Path string
+	Err  error
+	Time time.Time
+}
+
 // LiveStats holds the real-time scan statistics
 type LiveStats struct {
 	ExtensionStats map[string]*ExtStats
@@ -54,6 +45,7 @@ type LiveStats struct {
 	TotalSize      int64
 	FilesScanned   int
 	DirsScanned    int
+	Errors         []ScanError
 	mutex          sync.Mutex
 }
 
@@ -71,53 +63,6 @@ func formatSize(sizeBytes int64) string {
 	return fmt.Sprintf("%.2f %cB", float64(sizeBytes)/float64(div), "KMGTPE"[exp])
 }
 
-// Get file extension for categorization
-func getFileExtension(filename string) string {
-	lowername := strings.ToLower(filename)
-	
-	// Special handling for RAR parts (like those in the image)
-	if rarPartPattern.MatchString(lowername) {
-		return ".rXX"  // Group all RAR parts together
-	}
-	
-	// Special handling for numbered files
-	if numberedPattern.MatchString(lowername) {
-		return ".numbered"
-	}
-	
-	return strings.ToLower(filepath.Ext(filename))
-}
-
-// Check if a file is unwanted
-func isUnwantedFile(filename string) bool {
-	lowername := strings.ToLower(filename)
-
-	// Never delete files with safe extensions
-	for _, ext := range safeExtensions {
-		if strings.HasSuffix(lowername, ext) {
-			return false
-		}
-	}
-
-	// Check if file has an unwanted extension
-	for _, ext := range unwantedExtensions {
-		if strings.HasSuffix(lowername, ext) {
-			return true
-		}
-	}
-
-	// Check if file matches RAR part pattern (-.r08, -.r09, etc.)
-	if rarPartPattern.MatchString(lowername) {
-		return true
-	}
-
-	// Check if file matches a pattern (like .001, .002, etc.)
-	if numberedPattern.MatchString(lowername) || partPattern.MatchString(lowername) {
-		return true
-	}
-
-	return false
-}
 
 // FileTypeItem represents a row in the table
 type FileTypeItem struct {
@@ -156,8 +101,155 @@ func (m *FileTypeTableModel) Value(row, col int) interface{} {
 	return nil
 }
 
-// Delete files with cancellation support
-func deleteFiles(ctx context.Context, files []FileInfo, progressBar *walk.ProgressBar) (int, int64) {
+// ScanErrorItem represents a row in the scan errors table.
+type ScanErrorItem struct {
+	Path string
+	Err  string
+	Time string
+}
+
+// ScanErrorTableModel implements walk.TableModel
+type ScanErrorTableModel struct {
+	walk.TableModelBase
+	items []ScanErrorItem
+}
+
+func (m *ScanErrorTableModel) RowCount() int {
+	return len(m.items)
+}
+
+func (m *ScanErrorTableModel) Value(row, col int) interface{} {
+	if row < 0 || row >= len(m.items) {
+		return nil
+	}
+
+	item := m.items[row]
+
+	switch col {
+	case 0:
+		return item.Path
+	case 1:
+		return item.Err
+	case 2:
+		return item.Time
+	}
+
+	return nil
+}
+
+// PlexProtectedItem represents a row in the Plex-protected files table.
+type PlexProtectedItem struct {
+	Path   string
+	Reason string
+}
+
+// PlexProtectedTableModel implements walk.TableModel
+type PlexProtectedTableModel struct {
+	walk.TableModelBase
+	items []PlexProtectedItem
+}
+
+func (m *PlexProtectedTableModel) RowCount() int {
+	return len(m.items)
+}
+
+func (m *PlexProtectedTableModel) Value(row, col int) interface{} {
+	if row < 0 || row >= len(m.items) {
+		return nil
+	}
+
+	item := m.items[row]
+
+	switch col {
+	case 0:
+		return item.Path
+	case 1:
+		return item.Reason
+	}
+
+	return nil
+}
+
+// CompanionItem represents a row in the companion-protected files table.
+type CompanionItem struct {
+	Path      string
+	MediaPath string
+}
+
+// CompanionTableModel implements walk.TableModel
+type CompanionTableModel struct {
+	walk.TableModelBase
+	items []CompanionItem
+}
+
+func (m *CompanionTableModel) RowCount() int {
+	return len(m.items)
+}
+
+func (m *CompanionTableModel) Value(row, col int) interface{} {
+	if row < 0 || row >= len(m.items) {
+		return nil
+	}
+
+	item := m.items[row]
+
+	switch col {
+	case 0:
+		return item.Path
+	case 1:
+		return item.MediaPath
+	}
+
+	return nil
+}
+
+// ArchiveGroupItem represents a row in the archive verification table.
+type ArchiveGroupItem struct {
+	Base      string
+	PartCount int
+	Status    string
+}
+
+// ArchiveGroupTableModel implements walk.TableModel
+type ArchiveGroupTableModel struct {
+	walk.TableModelBase
+	items []ArchiveGroupItem
+}
+
+func (m *ArchiveGroupTableModel) RowCount() int {
+	return len(m.items)
+}
+
+func (m *ArchiveGroupTableModel) Value(row, col int) interface{} {
+	if row < 0 || row >= len(m.items) {
+		return nil
+	}
+
+	item := m.items[row]
+
+	switch col {
+	case 0:
+		return item.Base
+	case 1:
+		return item.PartCount
+	case 2:
+		return item.Status
+	}
+
+	return nil
+}
+
+// logScanError writes a scan error to the log with structured fields so
+// it can be grepped for after the fact.
+func logScanError(e ScanError) {
+	log.Printf("scan_error path=%q err=%q time=%q\n", e.Path, e.Err, e.Time.Format(time.RFC3339))
+}
+
+// Delete files with cancellation support. Files are handed to deleter,
+// which owns the actual removal (permanent, recycle bin, or quarantine)
+// and transparently retries deletions that fail because the file is
+// still open.
+func deleteFiles(ctx context.Context, files []FileInfo, progressBar *walk.ProgressBar, deleter *Deleter, mode DeleteMode) (int, int64) {
 	deletedCount := 0
 	var deletedSize int64
 
@@ -172,13 +264,10 @@ func deleteFiles(ctx context.Context, files []FileInfo, progressBar *walk.Progre
 			// Continue processing
 		}
 
-		err := os.Remove(file.Path)
-		if err != nil {
-			log.Printf("Error deleting %s: %v\n", file.Path, err)
+		if err := deleter.Enqueue(file, mode); err != nil {
 			continue
 		}
 
-		log.Printf("Deleted: %s (%s)\n", file.Path, formatSize(file.Size))
 		deletedCount++
 		deletedSize += file.Size
 		progressBar.SetValue(i + 1)
@@ -197,32 +286,155 @@ func main() {
 	defer f.Close()
 	log.SetOutput(f)
 
+	// Deleter owns the actual file removal (permanent, recycle bin, or
+	// quarantine) and its async retry queue; Close it on window close so
+	// in-flight retries get a chance to drain.
+	deleter := NewDeleter()
+	defer deleter.Close()
+
+	// Rule engine driving what counts as "unwanted"; starts from the
+	// ruleset embedded in the binary and can be swapped out or
+	// hot-reloaded from the Rules dialog. It's stored behind an atomic
+	// pointer because reloading happens on the UI thread while a scan
+	// goroutine may be reading it concurrently.
+	defaultRules, err := DefaultRuleSet()
+	if err != nil {
+		walk.MsgBox(nil, "Error", "Failed to load default rule set: "+err.Error(), walk.MsgBoxIconError)
+		return
+	}
+	var ruleSet atomic.Pointer[RuleSet]
+	ruleSet.Store(defaultRules)
+	var ruleSetPath string
+
 	var mainWindow *walk.MainWindow
 	var dirEdit *walk.LineEdit
 	var tableView *walk.TableView
+	var errorsTableView *walk.TableView
+	var archiveTableView *walk.TableView
+	var deleteUnverifiedCheck *walk.CheckBox
+	var companionsTableView *walk.TableView
+	var previewTree *walk.TreeView
+	var previewFilterEdit *walk.LineEdit
+	var plexBaseURLEdit *walk.LineEdit
+	var plexTokenEdit *walk.LineEdit
+	var plexSectionEdit *walk.LineEdit
+	var plexStatusLabel *walk.Label
+	var plexProtectedTableView *walk.TableView
 	var statusLabel *walk.Label
 	var scanStatsLabel *walk.Label
 	var progressBar *walk.ProgressBar
 	var deleteBtn *walk.PushButton
 	var scanBtn *walk.PushButton
 	var cancelBtn *walk.PushButton
-	
+	var quarantineDirEdit *walk.LineEdit
+	var quarantineAgeEdit *walk.NumberEdit
+	var quarantineBtn *walk.PushButton
+	var permanentDeleteRadio *walk.RadioButton
+	var rulesStatusLabel *walk.Label
+
 	// Context and cancel function for scan operations
 	var cancelScan context.CancelFunc
 	var ctx context.Context
-	
+
 	// Create live stats
 	liveStats := &LiveStats{
 		ExtensionStats: make(map[string]*ExtStats),
 	}
-	
+
 	// Create the table model
 	model := new(FileTypeTableModel)
-	
-	// List of unwanted files found during scan
-	var unwantedFiles []FileInfo
 
-	MainWindow{
+	// Table model for per-path errors encountered during the walk.
+	errorModel := new(ScanErrorTableModel)
+
+	// Table model for per-archive-group SFV verification results.
+	archiveModel := new(ArchiveGroupTableModel)
+
+	// Table model for files kept because they're a companion of a media
+	// file (poster art, NFO, subtitles, etc.).
+	companionModel := new(CompanionTableModel)
+
+	// Table model for files kept because Plex reports them in use.
+	plexProtectedModel := new(PlexProtectedTableModel)
+
+	// Plex server connection, set once the user saves the Plex
+	// Integration settings; nil means the integration is disabled.
+	var plexClient *plexapi.Client
+
+	// List of unwanted files found during scan that are not part of an
+	// archive group (deleted unconditionally) and the archive groups
+	// themselves (deleted based on their verification status and the
+	// "delete unverified archives" toggle).
+	var nonArchiveFiles []FileInfo
+	var archiveGroups map[string]*ArchiveGroup
+
+	// Full (unfiltered) preview tree for the current scan, the scanned
+	// root it belongs to, and the on-disk cache of the user's manual
+	// per-file overrides for that root.
+	var previewModel *FileTreeModel
+	var scanRoot string
+	var selectionCache *SelectionCache
+
+	// refreshPreviewTree re-renders previewTree from previewModel,
+	// applying the current filter box text.
+	refreshPreviewTree := func() {
+		if previewModel == nil || previewTree == nil {
+			return
+		}
+		previewTree.SetModel(FilterTree(previewModel, previewFilterEdit.Text()))
+	}
+
+	// rebuildPreviewTree regenerates previewModel from the current scan
+	// results and the "delete unverified archives" toggle, so flipping
+	// that checkbox after a scan has already run actually changes which
+	// archive parts default to checked instead of doing nothing. Any
+	// manual per-file/per-folder toggles already made in the preview
+	// tree are folded into selectionCache first, so rebuilding never
+	// silently discards them.
+	rebuildPreviewTree := func() {
+		if scanRoot == "" {
+			return
+		}
+
+		if previewModel != nil && selectionCache != nil {
+			selectionCache.Paths = CollectOverrides(previewModel)
+		}
+
+		previewCandidates := append([]FileInfo(nil), nonArchiveFiles...)
+		defaultUnchecked := make(map[string]bool)
+		for _, g := range archiveGroups {
+			previewCandidates = append(previewCandidates, g.Files...)
+			if g.Status != VerifyVerified && !deleteUnverifiedCheck.Checked() {
+				for _, f := range g.Files {
+					defaultUnchecked[f.Path] = true
+				}
+			}
+		}
+
+		previewModel = BuildFileTree(scanRoot, previewCandidates, defaultUnchecked, selectionCache)
+		refreshPreviewTree()
+	}
+
+	// toggleCurrentPreviewItem flips the checked state of whichever
+	// node is selected in previewTree, including all its descendants.
+	toggleCurrentPreviewItem := func() {
+		if previewModel == nil || previewTree == nil || len(previewModel.roots) == 0 {
+			return
+		}
+		node, ok := previewTree.CurrentItem().(*FileTreeNode)
+		if !ok {
+			return
+		}
+		ToggleNode(previewModel.roots[0], node)
+		refreshPreviewTree()
+	}
+
+	// How the user currently wants deleted files disposed of.
+	deleteMode := DeleteModePermanent
+
+	var stopQuarantinePurge func()
+
+	mw := MainWindow{
 		AssignTo: &mainWindow,
 		Title:    "Plex Cleanup Tool",
 		MinSize:  Size{600, 600},
@@ -235,6 +447,167 @@ func main() {
 			}
 		},
 		Children: []Widget{
+			GroupBox{
+				Title:  "Deletion Mode",
+				Layout: HBox{},
+				Children: []Widget{
+					RadioButtonGroupBox{
+						Layout: HBox{},
+						Children: []Widget{
+							RadioButton{
+								AssignTo: &permanentDeleteRadio,
+								Text:     "Permanent delete",
+								OnClicked: func() {
+									deleteMode = DeleteModePermanent
+								},
+							},
+							RadioButton{
+								Text: "Recycle bin",
+								OnClicked: func() {
+									deleteMode = DeleteModeRecycleBin
+								},
+							},
+							RadioButton{
+								Text: "Quarantine directory",
+								OnClicked: func() {
+									deleteMode = DeleteModeQuarantine
+								},
+							},
+						},
+					},
+					LineEdit{
+						AssignTo: &quarantineDirEdit,
+						ReadOnly: true,
+					},
+					Label{Text: "Age (days):"},
+					NumberEdit{
+						AssignTo: &quarantineAgeEdit,
+						MinValue: 1,
+						MaxValue: 365,
+						Decimals: 0,
+						Value:    7,
+						OnValueChanged: func() {
+							deleter.QuarantineAge = time.Duration(quarantineAgeEdit.Value()) * 24 * time.Hour
+						},
+					},
+					PushButton{
+						AssignTo: &quarantineBtn,
+						Text:     "Choose...",
+						OnClicked: func() {
+							dlg := new(walk.FileDialog)
+							dlg.Title = "Select Quarantine Directory"
+							dlg.FilePath = quarantineDirEdit.Text()
+
+							if ok, _ := dlg.ShowBrowseFolder(mainWindow); !ok {
+								return
+							}
+
+							quarantineDirEdit.SetText(dlg.FilePath)
+							deleter.QuarantineDir = dlg.FilePath
+							deleter.QuarantineAge = time.Duration(quarantineAgeEdit.Value()) * 24 * time.Hour
+
+							if stopQuarantinePurge != nil {
+								stopQuarantinePurge()
+							}
+							stopQuarantinePurge = deleter.StartQuarantinePurge(1 * time.Hour)
+						},
+					},
+				},
+			},
+			GroupBox{
+				Title:  "Plex Integration",
+				Layout: HBox{},
+				Children: []Widget{
+					Label{Text: "Base URL:"},
+					LineEdit{
+						AssignTo: &plexBaseURLEdit,
+						Text:     "http://127.0.0.1:32400",
+					},
+					Label{Text: "Token:"},
+					LineEdit{
+						AssignTo:     &plexTokenEdit,
+						PasswordMode: true,
+					},
+					Label{Text: "Section ID:"},
+					LineEdit{
+						AssignTo: &plexSectionEdit,
+					},
+					PushButton{
+						Text: "Save",
+						OnClicked: func() {
+							baseURL := strings.TrimRight(plexBaseURLEdit.Text(), "/")
+							token := plexTokenEdit.Text()
+
+							if baseURL == "" || token == "" {
+								plexClient = nil
+								plexStatusLabel.SetText("Plex integration disabled")
+								return
+							}
+
+							plexClient = plexapi.NewClient(baseURL, token)
+							plexStatusLabel.SetText("Plex integration enabled")
+						},
+					},
+					Label{
+						AssignTo: &plexStatusLabel,
+						Text:     "Plex integration disabled",
+					},
+				},
+			},
+			GroupBox{
+				Title:  "Rules",
+				Layout: HBox{},
+				Children: []Widget{
+					Label{
+						Text: "Using embedded default rule set",
+						AssignTo: &rulesStatusLabel,
+					},
+					HSpacer{},
+					PushButton{
+						Text: "Load Rules...",
+						OnClicked: func() {
+							dlg := new(walk.FileDialog)
+							dlg.Title = "Select Rule Set"
+							dlg.Filter = "Rule sets (*.yaml;*.yml;*.json)|*.yaml;*.yml;*.json|All Files (*.*)|*.*"
+
+							if ok, _ := dlg.ShowOpen(mainWindow); !ok {
+								return
+							}
+
+							loaded, err := LoadRuleSet(dlg.FilePath)
+							if err != nil {
+								walk.MsgBox(mainWindow, "Error", "Failed to load rule set: "+err.Error(), walk.MsgBoxIconError)
+								return
+							}
+
+							ruleSet.Store(loaded)
+							ruleSetPath = dlg.FilePath
+							rulesStatusLabel.SetText("Using " + filepath.Base(ruleSetPath))
+						},
+					},
+					PushButton{
+						Text: "Reload",
+						OnClicked: func() {
+							var loaded *RuleSet
+							var err error
+
+							if ruleSetPath == "" {
+								loaded, err = DefaultRuleSet()
+							} else {
+								loaded, err = LoadRuleSet(ruleSetPath)
+							}
+
+							if err != nil {
+								walk.MsgBox(mainWindow, "Error", "Failed to reload rule set: "+err.Error(), walk.MsgBoxIconError)
+								return
+							}
+
+							ruleSet.Store(loaded)
+							walk.MsgBox(mainWindow, "Rules Reloaded", "Rule set reloaded successfully.", walk.MsgBoxIconInformation)
+						},
+					},
+				},
+			},
 			GroupBox{
 				Title:  "Directory Selection",
 				Layout: HBox{},
@@ -256,17 +629,29 @@ func main() {
 							}
 							
 							dirEdit.SetText(dlg.FilePath)
-							
+
 							// Reset stats and UI
 							liveStats = &LiveStats{
 								ExtensionStats: make(map[string]*ExtStats),
 							}
-							unwantedFiles = nil
-							
+							nonArchiveFiles = nil
+							archiveGroups = nil
+							archiveModel.items = nil
+							archiveTableView.SetModel(archiveModel)
+							companionModel.items = nil
+							companionsTableView.SetModel(companionModel)
+
+							scanRoot = dlg.FilePath
+							selectionCache = LoadSelectionCache(scanRoot)
+							previewModel = nil
+							previewTree.SetModel(new(FileTreeModel))
+
 							// Update UI
 							model.items = nil
 							tableView.SetModel(model)
-							
+							errorModel.items = nil
+							errorsTableView.SetModel(errorModel)
+
 							// Update button states
 							deleteBtn.SetEnabled(false)
 							scanBtn.SetEnabled(false)
@@ -281,8 +666,18 @@ func main() {
 							progressBar.SetValue(0)
 							progressBar.SetRange(0, 100)
 							
+							// Read widget state needed inside the goroutine now,
+							// on the UI thread, since walk widgets aren't safe
+							// to touch from a background goroutine.
+							plexSectionID := plexSectionEdit.Text()
+
 							// Run scan in goroutine
 							go func() {
+								// Snapshot the active rule set once so this scan
+								// sees a consistent view even if the user reloads
+								// rules from the dialog while it's running.
+								rules := ruleSet.Load()
+
 								// Create update UI function
 								updateUI := func() {
 									mainWindow.Synchronize(func() {
@@ -299,15 +694,8 @@ func main() {
 										
 										// Add each extension type
 										for ext, stat := range liveStats.ExtensionStats {
-											displayExt := ext
-											if ext == ".numbered" {
-												displayExt = "Numbered files (.001, .002, etc.)"
-											} else if ext == ".rXX" {
-												displayExt = "RAR parts (S01E01 -.r08, -.r09, etc.)"
-											}
-											
 											model.items = append(model.items, FileTypeItem{
-												FileType:  displayExt,
+												FileType:  ext,
 												Count:     stat.Count,
 												TotalSize: formatSize(stat.Size),
 											})
@@ -323,14 +711,25 @@ func main() {
 											})
 										}
 										
+										// Update the scan errors table
+										errorModel.items = make([]ScanErrorItem, len(liveStats.Errors))
+										for i, e := range liveStats.Errors {
+											errorModel.items[i] = ScanErrorItem{
+												Path: e.Path,
+												Err:  e.Err.Error(),
+												Time: e.Time.Format(time.RFC3339),
+											}
+										}
+										errorsTableView.SetModel(errorModel)
+
 										// Refresh table
 										tableView.SetModel(model)
 									})
 								}
-								
+
 								// Collect unwanted files
 								var filesToDelete []FileInfo
-								
+
 								// Run scan
 								err := filepath.Walk(dlg.FilePath, func(path string, info os.FileInfo, err error) error {
 									// Check for cancellation
@@ -340,11 +739,20 @@ func main() {
 									default:
 										// Continue processing
 									}
-									
+
 									if err != nil {
-										return err
+										// Record the error and continue the walk instead of
+										// aborting the whole scan on a single bad path
+										// (permission denied, unreadable dir, stat failure).
+										scanErr := ScanError{Path: path, Err: err, Time: time.Now()}
+										liveStats.mutex.Lock()
+										liveStats.Errors = append(liveStats.Errors, scanErr)
+										liveStats.mutex.Unlock()
+										logScanError(scanErr)
+										updateUI()
+										return nil
 									}
-									
+
 									// Update directory count
 									if info.IsDir() {
 										liveStats.mutex.Lock()
@@ -359,8 +767,8 @@ func main() {
 									liveStats.FilesScanned++
 									
 									// Check if file is unwanted
-									if isUnwantedFile(info.Name()) {
-										fileExt := getFileExtension(info.Name())
+									if rules.IsUnwantedFile(path, info.Size()) {
+										fileExt := rules.GetExtension(info.Name())
 										
 										if _, exists := liveStats.ExtensionStats[fileExt]; !exists {
 											liveStats.ExtensionStats[fileExt] = &ExtStats{}
@@ -389,12 +797,84 @@ func main() {
 								
 								// Final update
 								updateUI()
-								
-								// Store unwanted files for deletion
-								unwantedFiles = filesToDelete
-								
+
+								// Group archive parts by directory + base name and verify
+								// each group against its sibling .sfv file concurrently,
+								// so the delete step can skip unverified archives.
+								groups, rest := SplitArchiveGroups(filesToDelete)
+								VerifyGroups(groups, 4)
+
+								archiveItems := make([]ArchiveGroupItem, 0, len(groups))
+								for _, g := range groups {
+									archiveItems = append(archiveItems, ArchiveGroupItem{
+										Base:      g.Base,
+										PartCount: len(g.Files),
+										Status:    string(g.Status),
+									})
+								}
+
+								// Spare companion files (posters, fanart, subtitles, NFOs)
+								// that sit next to a media file Plex actually uses.
+								var companions []CompanionFile
+								if rules.PreserveCompanions {
+									companions, rest = DetectCompanions(rest, rules.SafeExtensions)
+								}
+
+								companionItems := make([]CompanionItem, len(companions))
+								for i, c := range companions {
+									companionItems[i] = CompanionItem{Path: c.Path, MediaPath: c.MediaPath}
+								}
+
+								// Skip candidates Plex reports as actively streaming,
+								// or that are tracked library files (deleting them
+								// would orphan the Plex item), when integration is
+								// configured.
+								var plexProtectedItems []PlexProtectedItem
+								if plexClient != nil {
+									inUse := PlexInUsePaths(plexClient)
+									var libraryPaths map[string]bool
+									if plexSectionID != "" {
+										libraryPaths = PlexLibraryPaths(plexClient, plexSectionID)
+									}
+
+									var stillUnwanted []FileInfo
+									for _, file := range rest {
+										normalized := normalizePlexPath(file.Path)
+
+										if inUse[normalized] {
+											plexProtectedItems = append(plexProtectedItems, PlexProtectedItem{Path: file.Path, Reason: "Currently streaming"})
+											log.Printf("plex_protected path=%q reason=streaming\n", file.Path)
+											continue
+										}
+										if libraryPaths[normalized] {
+											plexProtectedItems = append(plexProtectedItems, PlexProtectedItem{Path: file.Path, Reason: "Tracked Plex library item"})
+											log.Printf("plex_protected path=%q reason=library_item\n", file.Path)
+											continue
+										}
+
+										stillUnwanted = append(stillUnwanted, file)
+									}
+									rest = stillUnwanted
+								}
+
 								// Update UI in UI thread with final results
 								mainWindow.Synchronize(func() {
+									nonArchiveFiles = rest
+									archiveGroups = groups
+									archiveModel.items = archiveItems
+									archiveTableView.SetModel(archiveModel)
+									companionModel.items = companionItems
+									companionsTableView.SetModel(companionModel)
+									plexProtectedModel.items = plexProtectedItems
+									plexProtectedTableView.SetModel(plexProtectedModel)
+
+									// Build the dry-run preview tree: every remaining
+									// candidate, defaulting to checked except unverified
+									// archive parts (unless "delete unverified archives"
+									// is on), then overridden by whatever the user chose
+									// the last time this root was scanned.
+									rebuildPreviewTree()
+
 									if err != nil && err != context.Canceled {
 										walk.MsgBox(mainWindow, "Error", "Failed to scan directory: "+err.Error(), walk.MsgBoxIconError)
 										statusLabel.SetText("Scan failed")
@@ -434,27 +914,140 @@ func main() {
 				AssignTo: &scanStatsLabel,
 				Text:     "Files scanned: 0 | Directories scanned: 0",
 			},
-			TableView{
-				AssignTo:      &tableView,
+			TabWidget{
 				StretchFactor: 2,
-				Columns: []TableViewColumn{
-					{Title: "File Type", Width: 250},
-					{Title: "Count", Width: 100},
-					{Title: "Total Size", Width: 150},
-				},
-				StyleCell: func(style *walk.CellStyle) {
-					if len(model.items) <= style.Row() {
-						return
-					}
-					
-					item := model.items[style.Row()]
-					
-					if item.IsTotal {
-						style.TextColor = walk.RGB(0, 0, 128)
-						if font, err := walk.NewFont("Segoe UI", 9, walk.FontBold); err == nil {
-							style.Font = font
-						}
-					}
+				Pages: []TabPage{
+					TabPage{
+						Title:  "Unwanted Files",
+						Layout: VBox{},
+						Children: []Widget{
+							TableView{
+								AssignTo: &tableView,
+								Columns: []TableViewColumn{
+									{Title: "File Type", Width: 250},
+									{Title: "Count", Width: 100},
+									{Title: "Total Size", Width: 150},
+								},
+								StyleCell: func(style *walk.CellStyle) {
+									if len(model.items) <= style.Row() {
+										return
+									}
+
+									item := model.items[style.Row()]
+
+									if item.IsTotal {
+										style.TextColor = walk.RGB(0, 0, 128)
+										if font, err := walk.NewFont("Segoe UI", 9, walk.FontBold); err == nil {
+											style.Font = font
+										}
+									}
+								},
+							},
+						},
+					},
+					TabPage{
+						Title:  "Scan Errors",
+						Layout: VBox{},
+						Children: []Widget{
+							TableView{
+								AssignTo: &errorsTableView,
+								Columns: []TableViewColumn{
+									{Title: "Path", Width: 350},
+									{Title: "Error", Width: 250},
+									{Title: "Time", Width: 140},
+								},
+							},
+							PushButton{
+								Text: "Copy errors to clipboard",
+								OnClicked: func() {
+									liveStats.mutex.Lock()
+									lines := make([]string, 0, len(liveStats.Errors))
+									for _, e := range liveStats.Errors {
+										lines = append(lines, fmt.Sprintf("%s\t%v\t%s", e.Path, e.Err, e.Time.Format(time.RFC3339)))
+									}
+									liveStats.mutex.Unlock()
+
+									if err := walk.Clipboard().SetText(strings.Join(lines, "\n")); err != nil {
+										walk.MsgBox(mainWindow, "Error", "Failed to copy errors: "+err.Error(), walk.MsgBoxIconError)
+									}
+								},
+							},
+						},
+					},
+					TabPage{
+						Title:  "Archive Verification",
+						Layout: VBox{},
+						Children: []Widget{
+							TableView{
+								AssignTo: &archiveTableView,
+								Columns: []TableViewColumn{
+									{Title: "Archive", Width: 300},
+									{Title: "Parts", Width: 80},
+									{Title: "Status", Width: 200},
+								},
+							},
+							CheckBox{
+								AssignTo: &deleteUnverifiedCheck,
+								Text:     "Delete unverified archives (no .sfv, missing parts, or checksum mismatch)",
+								OnCheckedChanged: func() {
+									rebuildPreviewTree()
+								},
+							},
+						},
+					},
+					TabPage{
+						Title:  "Companion-Protected Files",
+						Layout: VBox{},
+						Children: []Widget{
+							TableView{
+								AssignTo: &companionsTableView,
+								Columns: []TableViewColumn{
+									{Title: "Protected File", Width: 300},
+									{Title: "Because Of", Width: 300},
+								},
+							},
+						},
+					},
+					TabPage{
+						Title:  "Preview",
+						Layout: VBox{},
+						Children: []Widget{
+							Composite{
+								Layout: HBox{},
+								Children: []Widget{
+									Label{Text: "Filter:"},
+									LineEdit{
+										AssignTo: &previewFilterEdit,
+										OnTextChanged: func() {
+											refreshPreviewTree()
+										},
+									},
+									PushButton{
+										Text: "Toggle Selected",
+										OnClicked: func() {
+											toggleCurrentPreviewItem()
+										},
+									},
+								},
+							},
+							TreeView{
+								AssignTo: &previewTree,
+							},
+						},
+					},
+					TabPage{
+						Title:  "Plex-Protected Files",
+						Layout: VBox{},
+						Children: []Widget{
+							TableView{
+								AssignTo: &plexProtectedTableView,
+								Columns: []TableViewColumn{
+									{Title: "Protected File", Width: 350},
+									{Title: "Reason", Width: 250},
+								},
+							},
+						},
+					},
 				},
 			},
 			Label{
@@ -474,38 +1067,73 @@ func main() {
 						Text:      "Delete Unwanted Files",
 						Enabled:   false,
 						OnClicked: func() {
-							if len(unwantedFiles) == 0 {
+							// The preview tree's checked leaves are authoritative:
+							// deleteBtn only enables after a scan has built one,
+							// and they already start from the non-archive/
+							// verified-archive default (as adjusted by "delete
+							// unverified archives") reflecting whatever the user
+							// hand-picked in the Preview tab.
+							if previewModel == nil {
 								return
 							}
-							
+
+							filesToDelete := CollectChecked(previewModel)
+
+							if selectionCache != nil {
+								selectionCache.Paths = CollectOverrides(previewModel)
+								if err := selectionCache.Save(); err != nil {
+									log.Printf("Error saving selection cache: %v\n", err)
+								}
+							}
+
+							if len(filesToDelete) == 0 {
+								return
+							}
+
+							var selectedSize int64
+							for _, file := range filesToDelete {
+								selectedSize += file.Size
+							}
+
 							// Confirm deletion
-							if walk.MsgBox(mainWindow, "Confirm Deletion", 
-								fmt.Sprintf("Are you sure you want to delete %d files (%s)?", 
-									liveStats.TotalFiles, formatSize(liveStats.TotalSize)), 
+							if walk.MsgBox(mainWindow, "Confirm Deletion",
+								fmt.Sprintf("Are you sure you want to delete %d files (%s)?",
+									len(filesToDelete), formatSize(selectedSize)),
 									walk.MsgBoxOKCancel|walk.MsgBoxIconQuestion) != walk.DlgCmdOK {
 								return
 							}
-							
+
 							// Disable buttons during deletion
 							deleteBtn.SetEnabled(false)
 							scanBtn.SetEnabled(false)
 							cancelBtn.SetEnabled(true)
 							statusLabel.SetText("Deleting files...")
-							
+
 							// Create cancellable context for deletion
 							ctx, cancelScan = context.WithCancel(context.Background())
-							
+
+							// Read widget state needed inside the goroutine now,
+							// on the UI thread, since walk widgets aren't safe
+							// to touch from a background goroutine.
+							plexSectionID := plexSectionEdit.Text()
+
 							// Delete files in goroutine
 							go func() {
 								startTime := time.Now()
-								deletedCount, deletedSize := deleteFiles(ctx, unwantedFiles, progressBar)
+								deletedCount, deletedSize := deleteFiles(ctx, filesToDelete, progressBar, deleter, deleteMode)
 								duration := time.Since(startTime)
 								
 								// Log results
 								log.Printf("Cleanup completed in %.2f seconds\n", duration.Seconds())
 								log.Printf("Total files deleted: %d\n", deletedCount)
 								log.Printf("Total space freed: %s\n", formatSize(deletedSize))
-								
+
+								// Let Plex reconcile its database with the files
+								// that were just removed.
+								if plexClient != nil {
+									RefreshPlexSection(plexClient, plexSectionID)
+								}
+
 								// Update UI
 								mainWindow.Synchronize(func() {
 									cancelBtn.SetEnabled(false)
@@ -529,10 +1157,15 @@ func main() {
 									liveStats = &LiveStats{
 										ExtensionStats: make(map[string]*ExtStats),
 									}
-									unwantedFiles = nil
+									nonArchiveFiles = nil
+									archiveGroups = nil
+									archiveModel.items = nil
+									archiveTableView.SetModel(archiveModel)
+									previewModel = nil
+									previewTree.SetModel(new(FileTreeModel))
 									model.items = nil
 									tableView.SetModel(model)
-									
+
 									scanStatsLabel.SetText("Files scanned: 0 | Directories scanned: 0")
 								})
 							}()
@@ -552,5 +1185,20 @@ func main() {
 				},
 			},
 		},
-	}.Run()
+	}
+
+	if err := mw.Create(); err != nil {
+		log.Fatal(err)
+	}
+
+	permanentDeleteRadio.SetChecked(true)
+
+	mainWindow.Closing().Attach(func(canceled *bool, reason walk.CloseReason) {
+		if stopQuarantinePurge != nil {
+			stopQuarantinePurge()
+		}
+		deleter.Close()
+	})
+
+	mainWindow.Run()
 }
@@ -0,0 +1,125 @@
+// Package plexapi is a small typed client for the subset of the Plex
+// Media Server HTTP API that plex_cleanup needs: listing active
+// sessions, mapping library items to filesystem paths, and triggering a
+// library refresh after cleanup.
+package plexapi
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Client talks to a single Plex Media Server, authenticating every
+// request with an X-Plex-Token.
+type Client struct {
+	BaseURL string
+	Token   string
+
+	HTTPClient *http.Client
+}
+
+// NewClient returns a Client for the server at baseURL, authenticating
+// with token.
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		BaseURL:    baseURL,
+		Token:      token,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Session is one entry from /status/sessions: a client currently
+// streaming something from this server.
+type Session struct {
+	Title string `xml:"title,attr"`
+	Media struct {
+		Part struct {
+			File string `xml:"file,attr"`
+		} `xml:"Part"`
+	} `xml:"Media"`
+}
+
+type sessionsResponse struct {
+	Sessions []Session `xml:"Video"`
+}
+
+// ActiveSessions returns every session currently streaming from the
+// server, so the cleanup scan can avoid deleting files still in use.
+func (c *Client) ActiveSessions(ctx context.Context) ([]Session, error) {
+	var resp sessionsResponse
+	if err := c.get(ctx, "/status/sessions", &resp); err != nil {
+		return nil, fmt.Errorf("fetching active sessions: %w", err)
+	}
+	return resp.Sessions, nil
+}
+
+// LibraryItem is one entry from /library/sections/{id}/all: a single
+// piece of media and the file(s) backing it.
+type LibraryItem struct {
+	Title string `xml:"title,attr"`
+	Media []struct {
+		Part []struct {
+			File string `xml:"file,attr"`
+		} `xml:"Part"`
+	} `xml:"Media"`
+}
+
+type libraryResponse struct {
+	Items []LibraryItem `xml:"Video"`
+}
+
+// LibrarySectionItems returns every item in library section sectionID,
+// used to map filesystem paths back to the Plex item that owns them.
+func (c *Client) LibrarySectionItems(ctx context.Context, sectionID string) ([]LibraryItem, error) {
+	var resp libraryResponse
+	if err := c.get(ctx, fmt.Sprintf("/library/sections/%s/all", sectionID), &resp); err != nil {
+		return nil, fmt.Errorf("fetching library section %s: %w", sectionID, err)
+	}
+	return resp.Items, nil
+}
+
+// RefreshSection asks the server to rescan library section sectionID,
+// so Plex reconciles its database after files have been deleted.
+func (c *Client) RefreshSection(ctx context.Context, sectionID string) error {
+	if err := c.get(ctx, fmt.Sprintf("/library/sections/%s/refresh", sectionID), nil); err != nil {
+		return fmt.Errorf("refreshing library section %s: %w", sectionID, err)
+	}
+	return nil
+}
+
+func (c *Client) get(ctx context.Context, path string, out interface{}) error {
+	u, err := url.Parse(c.BaseURL + path)
+	if err != nil {
+		return err
+	}
+
+	q := u.Query()
+	q.Set("X-Plex-Token", c.Token)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/xml")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, path)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	return xml.NewDecoder(resp.Body).Decode(out)
+}
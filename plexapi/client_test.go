@@ -0,0 +1,91 @@
+package plexapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return NewClient(server.URL, "test-token")
+}
+
+func TestActiveSessionsParsesSessionsAndSendsToken(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/status/sessions" {
+			t.Errorf("expected path /status/sessions, got %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("X-Plex-Token"); got != "test-token" {
+			t.Errorf("expected X-Plex-Token=test-token, got %q", got)
+		}
+
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<MediaContainer>
+			<Video title="Movie A">
+				<Media><Part file="/movies/A/A.mkv" /></Media>
+			</Video>
+		</MediaContainer>`))
+	})
+
+	sessions, err := client.ActiveSessions(context.Background())
+	if err != nil {
+		t.Fatalf("ActiveSessions() failed: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].Media.Part.File != "/movies/A/A.mkv" {
+		t.Errorf("expected one session for /movies/A/A.mkv, got %+v", sessions)
+	}
+}
+
+func TestLibrarySectionItemsParsesAllParts(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/library/sections/3/all" {
+			t.Errorf("expected path /library/sections/3/all, got %s", r.URL.Path)
+		}
+
+		w.Write([]byte(`<MediaContainer>
+			<Video title="Show S01E01">
+				<Media><Part file="/tv/Show/S01E01.mkv" /></Media>
+			</Video>
+		</MediaContainer>`))
+	})
+
+	items, err := client.LibrarySectionItems(context.Background(), "3")
+	if err != nil {
+		t.Fatalf("LibrarySectionItems() failed: %v", err)
+	}
+	if len(items) != 1 || items[0].Media[0].Part[0].File != "/tv/Show/S01E01.mkv" {
+		t.Errorf("expected one item for /tv/Show/S01E01.mkv, got %+v", items)
+	}
+}
+
+func TestRefreshSectionHitsRefreshEndpoint(t *testing.T) {
+	var requested bool
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+		if r.URL.Path != "/library/sections/7/refresh" {
+			t.Errorf("expected path /library/sections/7/refresh, got %s", r.URL.Path)
+		}
+	})
+
+	if err := client.RefreshSection(context.Background(), "7"); err != nil {
+		t.Fatalf("RefreshSection() failed: %v", err)
+	}
+	if !requested {
+		t.Error("expected the refresh endpoint to be requested")
+	}
+}
+
+func TestGetReturnsErrorOnNonOKStatus(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	if _, err := client.ActiveSessions(context.Background()); err == nil {
+		t.Error("expected an error for a non-200 response, got nil")
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Gooshy/plex_cleanup/plexapi"
+)
+
+// PlexInUsePaths queries the server for currently active sessions and
+// returns the set of files they're streaming, normalized for path
+// comparison against scan results.
+func PlexInUsePaths(client *plexapi.Client) map[string]bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	sessions, err := client.ActiveSessions(ctx)
+	if err != nil {
+		log.Printf("Plex: failed to fetch active sessions: %v\n", err)
+		return nil
+	}
+
+	inUse := make(map[string]bool, len(sessions))
+	for _, s := range sessions {
+		if s.Media.Part.File != "" {
+			inUse[normalizePlexPath(s.Media.Part.File)] = true
+		}
+	}
+	return inUse
+}
+
+// PlexLibraryPaths queries every file backing an item in sectionID, so
+// the scan can tell whether deleting a candidate would orphan a Plex
+// library entry.
+func PlexLibraryPaths(client *plexapi.Client, sectionID string) map[string]bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	items, err := client.LibrarySectionItems(ctx, sectionID)
+	if err != nil {
+		log.Printf("Plex: failed to fetch library section %s: %v\n", sectionID, err)
+		return nil
+	}
+
+	paths := make(map[string]bool)
+	for _, item := range items {
+		for _, media := range item.Media {
+			for _, part := range media.Part {
+				if part.File != "" {
+					paths[normalizePlexPath(part.File)] = true
+				}
+			}
+		}
+	}
+	return paths
+}
+
+func normalizePlexPath(path string) string {
+	return strings.ToLower(filepath.Clean(path))
+}
+
+// RefreshPlexSection triggers a library rescan after a deletion batch
+// completes, so Plex reconciles its database with the files that were
+// actually removed. Errors are logged, not surfaced, since a stale
+// library is cosmetic compared to the cleanup that already happened.
+func RefreshPlexSection(client *plexapi.Client, sectionID string) {
+	if client == nil || sectionID == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := client.RefreshSection(ctx, sectionID); err != nil {
+		log.Printf("Plex: failed to refresh section %s: %v\n", sectionID, err)
+		return
+	}
+	log.Printf("Plex: refreshed library section %s\n", sectionID)
+}
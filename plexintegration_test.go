@@ -0,0 +1,89 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/Gooshy/plex_cleanup/plexapi"
+)
+
+func newTestPlexClient(t *testing.T, handler http.HandlerFunc) *plexapi.Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return plexapi.NewClient(server.URL, "test-token")
+}
+
+func TestPlexInUsePathsNormalizesSessionFiles(t *testing.T) {
+	client := newTestPlexClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<MediaContainer>
+			<Video title="Movie A">
+				<Media><Part file="/Movies/A/A.MKV" /></Media>
+			</Video>
+		</MediaContainer>`))
+	})
+
+	inUse := PlexInUsePaths(client)
+	if !inUse[normalizePlexPath("/Movies/A/A.MKV")] {
+		t.Errorf("expected the streaming file to be reported in use, got %+v", inUse)
+	}
+}
+
+func TestPlexLibraryPathsCollectsEveryPart(t *testing.T) {
+	client := newTestPlexClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<MediaContainer>
+			<Video title="Show S01E01">
+				<Media><Part file="/TV/Show/S01E01.mkv" /></Media>
+			</Video>
+		</MediaContainer>`))
+	})
+
+	paths := PlexLibraryPaths(client, "3")
+	if !paths[normalizePlexPath("/TV/Show/S01E01.mkv")] {
+		t.Errorf("expected the library item's file to be collected, got %+v", paths)
+	}
+}
+
+func TestPlexLibraryPathsReturnsNilOnServerError(t *testing.T) {
+	client := newTestPlexClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	if paths := PlexLibraryPaths(client, "3"); paths != nil {
+		t.Errorf("expected nil paths when the server errors, got %+v", paths)
+	}
+}
+
+func TestNormalizePlexPathLowercasesAndCleans(t *testing.T) {
+	want := filepath.FromSlash("/movies/a/a.mkv")
+	if got := normalizePlexPath("/Movies/../Movies/A/A.MKV"); got != want {
+		t.Errorf("normalizePlexPath() = %q, want %q", got, want)
+	}
+}
+
+func TestRefreshPlexSectionSkipsWithoutClientOrSection(t *testing.T) {
+	// Must not panic when there's nothing configured to refresh.
+	RefreshPlexSection(nil, "3")
+
+	client := plexapi.NewClient("http://127.0.0.1:0", "token")
+	RefreshPlexSection(client, "")
+}
+
+func TestRefreshPlexSectionHitsRefreshEndpoint(t *testing.T) {
+	var requested bool
+	client := newTestPlexClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+		if r.URL.Path != "/library/sections/5/refresh" {
+			t.Errorf("expected path /library/sections/5/refresh, got %s", r.URL.Path)
+		}
+	})
+
+	RefreshPlexSection(client, "5")
+
+	if !requested {
+		t.Error("expected the refresh endpoint to be requested")
+	}
+}
@@ -0,0 +1,60 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	foDelete        = 0x0003
+	fofAllowUndo    = 0x0040
+	fofNoConfirmation = 0x0010
+	fofSilent       = 0x0004
+)
+
+// shFileOpStruct mirrors the Win32 SHFILEOPSTRUCTW layout used to drive
+// the recycle bin instead of a hard delete.
+type shFileOpStruct struct {
+	hwnd                  uintptr
+	wFunc                 uint32
+	pFrom                 *uint16
+	pTo                   *uint16
+	fFlags                uint16
+	fAnyOperationsAborted int32
+	hNameMappings         uintptr
+	lpszProgressTitle     *uint16
+}
+
+var (
+	shell32          = syscall.NewLazyDLL("shell32.dll")
+	procSHFileOperationW = shell32.NewProc("SHFileOperationW")
+)
+
+// moveToRecycleBin sends path to the OS recycle bin via SHFileOperation
+// rather than permanently deleting it.
+func moveToRecycleBin(path string) error {
+	// pFrom must be a double-null-terminated list of paths.
+	from, err := syscall.UTF16FromString(path)
+	if err != nil {
+		return err
+	}
+	from = append(from, 0)
+
+	op := shFileOpStruct{
+		wFunc:  foDelete,
+		pFrom:  &from[0],
+		fFlags: fofAllowUndo | fofNoConfirmation | fofSilent,
+	}
+
+	ret, _, _ := procSHFileOperationW.Call(uintptr(unsafe.Pointer(&op)))
+	if ret != 0 {
+		return fmt.Errorf("SHFileOperation failed for %s: code %d", path, ret)
+	}
+	if op.fAnyOperationsAborted != 0 {
+		return fmt.Errorf("recycle bin operation aborted for %s", path)
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed default_rules.yaml
+var defaultRulesFS embed.FS
+
+// RuleAction is what should happen to a file matched by a Rule.
+// ActionDelete and ActionQuarantine both mark the file unwanted, and it
+// is then disposed of by the deletion mode selected in the main window;
+// a quarantine rule does not move its file to the quarantine directory
+// by itself.
+type RuleAction string
+
+const (
+	ActionDelete     RuleAction = "delete"
+	ActionKeep       RuleAction = "keep"
+	ActionQuarantine RuleAction = "quarantine"
+)
+
+// Rule is a single named matcher in a RuleSet. A file matches a rule if
+// its name has one of Extensions as a suffix or matches one of Patterns,
+// and (when set) its size and path also satisfy MinSize/MaxSize and
+// PathGlob.
+type Rule struct {
+	Name       string     `yaml:"name" json:"name"`
+	Extensions []string   `yaml:"extensions,omitempty" json:"extensions,omitempty"`
+	Patterns   []string   `yaml:"patterns,omitempty" json:"patterns,omitempty"`
+	MinSize    int64      `yaml:"min_size,omitempty" json:"min_size,omitempty"`
+	MaxSize    int64      `yaml:"max_size,omitempty" json:"max_size,omitempty"`
+	PathGlob   string     `yaml:"path_glob,omitempty" json:"path_glob,omitempty"`
+	Action     RuleAction `yaml:"action" json:"action"`
+
+	compiled []*regexp.Regexp
+}
+
+// RuleSet replaces the old hard-coded extension globals: it's loaded
+// from a YAML or JSON config and can be hot-reloaded from the Rules
+// dialog without recompiling.
+type RuleSet struct {
+	SafeExtensions []string `yaml:"safe_extensions" json:"safe_extensions"`
+	Rules          []Rule   `yaml:"rules" json:"rules"`
+
+	// PreserveCompanions enables the "preserve companions of media
+	// files" rule: a same-basename file next to a safe-extension media
+	// file (Movie.2020.nfo next to Movie.2020.mkv) is kept instead of
+	// deleted even if it otherwise matches a delete rule. Archive parts
+	// grouped for .sfv verification, and the .sfv files folded into those
+	// groups, are not checked for companions.
+	PreserveCompanions bool `yaml:"preserve_companions" json:"preserve_companions"`
+}
+
+// DefaultRuleSet returns the ruleset embedded in the binary, used until
+// the user loads their own via the Rules dialog.
+func DefaultRuleSet() (*RuleSet, error) {
+	data, err := defaultRulesFS.ReadFile("default_rules.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("reading embedded default rule set: %w", err)
+	}
+	return parseRuleSet(data, "yaml")
+}
+
+// LoadRuleSet reads and compiles a RuleSet from a YAML or JSON file on
+// disk, the format chosen by the file extension.
+func LoadRuleSet(path string) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading rule set %s: %w", path, err)
+	}
+
+	format := "yaml"
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		format = "json"
+	}
+
+	return parseRuleSet(data, format)
+}
+
+func parseRuleSet(data []byte, format string) (*RuleSet, error) {
+	var rs RuleSet
+
+	var err error
+	if format == "json" {
+		err = json.Unmarshal(data, &rs)
+	} else {
+		err = yaml.Unmarshal(data, &rs)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing rule set: %w", err)
+	}
+
+	if err := rs.compile(); err != nil {
+		return nil, err
+	}
+
+	return &rs, nil
+}
+
+func (rs *RuleSet) compile() error {
+	for i := range rs.Rules {
+		rule := &rs.Rules[i]
+		rule.compiled = nil
+
+		for _, pattern := range rule.Patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("rule %q: invalid pattern %q: %w", rule.Name, pattern, err)
+			}
+			rule.compiled = append(rule.compiled, re)
+		}
+
+		if rule.Action == "" {
+			rule.Action = ActionDelete
+		}
+	}
+	return nil
+}
+
+// GetExtension returns the display category for filename: a matching
+// pattern rule's Name (grouping things like RAR parts or numbered files
+// together), or otherwise the lowercased file extension.
+func (rs *RuleSet) GetExtension(filename string) string {
+	lowername := strings.ToLower(filename)
+
+	for _, rule := range rs.Rules {
+		for _, re := range rule.compiled {
+			if re.MatchString(lowername) {
+				return rule.Name
+			}
+		}
+	}
+
+	return strings.ToLower(filepath.Ext(filename))
+}
+
+// IsUnwantedFile reports whether the file at fullPath (size bytes)
+// isn't protected by a safe extension and the first rule it matches, in
+// order, has a delete or quarantine action. A file no rule matches is
+// not unwanted.
+func (rs *RuleSet) IsUnwantedFile(fullPath string, size int64) bool {
+	filename := filepath.Base(fullPath)
+	lowername := strings.ToLower(filename)
+
+	for _, ext := range rs.SafeExtensions {
+		if strings.HasSuffix(lowername, strings.ToLower(ext)) {
+			return false
+		}
+	}
+
+	for _, rule := range rs.Rules {
+		if !rule.matches(fullPath, lowername, size) {
+			continue
+		}
+		return rule.Action == ActionDelete || rule.Action == ActionQuarantine
+	}
+
+	return false
+}
+
+func (r *Rule) matches(fullPath, lowername string, size int64) bool {
+	matched := false
+
+	for _, ext := range r.Extensions {
+		if strings.HasSuffix(lowername, strings.ToLower(ext)) {
+			matched = true
+			break
+		}
+	}
+
+	if !matched {
+		for _, re := range r.compiled {
+			if re.MatchString(lowername) {
+				matched = true
+				break
+			}
+		}
+	}
+
+	if !matched {
+		return false
+	}
+
+	if r.MinSize > 0 && size < r.MinSize {
+		return false
+	}
+	if r.MaxSize > 0 && size > r.MaxSize {
+		return false
+	}
+
+	if r.PathGlob != "" && !matchPathGlob(r.PathGlob, fullPath) {
+		return false
+	}
+
+	return true
+}
+
+// matchPathGlob matches path against glob, which may use "**" to mean
+// "any number of directories" (filepath.Match alone doesn't support
+// that), e.g. "Movies/**".
+func matchPathGlob(glob, path string) bool {
+	normalized := filepath.ToSlash(path)
+	glob = filepath.ToSlash(glob)
+
+	if idx := strings.Index(glob, "**"); idx >= 0 {
+		prefix := glob[:idx]
+		suffix := strings.TrimPrefix(glob[idx+2:], "/")
+		return strings.Contains(normalized, prefix) && (suffix == "" || strings.HasSuffix(normalized, suffix))
+	}
+
+	ok, _ := filepath.Match(glob, normalized)
+	return ok
+}
@@ -0,0 +1,80 @@
+package main
+
+import "testing"
+
+func TestRuleMatchesExtension(t *testing.T) {
+	r := Rule{Extensions: []string{".nfo"}, Action: ActionDelete}
+
+	if !r.matches("/movies/Movie.2020.nfo", "movie.2020.nfo", 100) {
+		t.Error("expected .nfo file to match")
+	}
+	if r.matches("/movies/Movie.2020.mkv", "movie.2020.mkv", 100) {
+		t.Error("did not expect .mkv file to match an .nfo rule")
+	}
+}
+
+func TestRuleMatchesSizeBounds(t *testing.T) {
+	r := Rule{Extensions: []string{".txt"}, MinSize: 100, MaxSize: 1000, Action: ActionDelete}
+
+	if r.matches("/a.txt", "a.txt", 50) {
+		t.Error("expected file below MinSize to not match")
+	}
+	if r.matches("/a.txt", "a.txt", 1001) {
+		t.Error("expected file above MaxSize to not match")
+	}
+	if !r.matches("/a.txt", "a.txt", 500) {
+		t.Error("expected file within size bounds to match")
+	}
+}
+
+func TestRuleMatchesPathGlob(t *testing.T) {
+	r := Rule{Extensions: []string{".jpg"}, PathGlob: "Movies/**", Action: ActionDelete}
+
+	if !r.matches("/plex/Movies/Movie.2020/poster.jpg", "poster.jpg", 100) {
+		t.Error("expected path under Movies/ to match")
+	}
+	if r.matches("/plex/TV/Show/poster.jpg", "poster.jpg", 100) {
+		t.Error("did not expect path outside Movies/ to match")
+	}
+}
+
+func TestMatchPathGlobDoubleStar(t *testing.T) {
+	cases := []struct {
+		glob, path string
+		want       bool
+	}{
+		{"Movies/**", "/plex/Movies/Movie.2020/Movie.2020.mkv", true},
+		{"Movies/**", "/plex/TV/Show/episode.mkv", false},
+		{"Movies/**/extras", "/plex/Movies/Movie.2020/extras", true},
+	}
+
+	for _, c := range cases {
+		if got := matchPathGlob(c.glob, c.path); got != c.want {
+			t.Errorf("matchPathGlob(%q, %q) = %v, want %v", c.glob, c.path, got, c.want)
+		}
+	}
+}
+
+func TestIsUnwantedFileRespectsSafeExtensions(t *testing.T) {
+	rs := &RuleSet{
+		SafeExtensions: []string{".mkv"},
+		Rules:          []Rule{{Extensions: []string{".nfo", ".mkv"}, Action: ActionDelete}},
+	}
+
+	if rs.IsUnwantedFile("/movies/Movie.2020.mkv", 100) {
+		t.Error("expected safe extension to never be unwanted, even if a rule also matches it")
+	}
+	if !rs.IsUnwantedFile("/movies/Movie.2020.nfo", 100) {
+		t.Error("expected .nfo to be unwanted")
+	}
+}
+
+func TestIsUnwantedFileKeepAction(t *testing.T) {
+	rs := &RuleSet{
+		Rules: []Rule{{Extensions: []string{".srt"}, Action: ActionKeep}},
+	}
+
+	if rs.IsUnwantedFile("/movies/Movie.2020.srt", 100) {
+		t.Error("expected ActionKeep rule to not mark the file unwanted")
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogScanErrorIncludesPathAndErr(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	logScanError(ScanError{
+		Path: "/movies/locked.mkv",
+		Err:  errors.New("access is denied"),
+		Time: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	})
+
+	out := buf.String()
+	if !strings.Contains(out, `path="/movies/locked.mkv"`) {
+		t.Errorf("expected log line to contain the path, got %q", out)
+	}
+	if !strings.Contains(out, `err="access is denied"`) {
+		t.Errorf("expected log line to contain the error, got %q", out)
+	}
+}
+
+func TestScanErrorTableModelValue(t *testing.T) {
+	m := &ScanErrorTableModel{items: []ScanErrorItem{
+		{Path: "/movies/locked.mkv", Err: "access is denied", Time: "2026-01-02T03:04:05Z"},
+	}}
+
+	if got := m.RowCount(); got != 1 {
+		t.Fatalf("RowCount() = %d, want 1", got)
+	}
+
+	want := []interface{}{"/movies/locked.mkv", "access is denied", "2026-01-02T03:04:05Z"}
+	for col, w := range want {
+		if got := m.Value(0, col); got != w {
+			t.Errorf("Value(0, %d) = %v, want %v", col, got, w)
+		}
+	}
+
+	if got := m.Value(0, 3); got != nil {
+		t.Errorf("Value(0, 3) = %v, want nil for an out-of-range column", got)
+	}
+	if got := m.Value(1, 0); got != nil {
+		t.Errorf("Value(1, 0) = %v, want nil for an out-of-range row", got)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	cases := []struct {
+		bytes int64
+		want  string
+	}{
+		{500, "500 B"},
+		{1024, "1.00 KB"},
+		{1536, "1.50 KB"},
+		{1024 * 1024, "1.00 MB"},
+	}
+
+	for _, c := range cases {
+		if got := formatSize(c.bytes); got != c.want {
+			t.Errorf("formatSize(%d) = %q, want %q", c.bytes, got, c.want)
+		}
+	}
+}
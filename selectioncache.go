@@ -0,0 +1,75 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// SelectionCache persists per-file checked/unchecked overrides for a
+// scanned root under %APPDATA%, so re-scanning the same root remembers
+// which candidates the user excluded last time (similar to how
+// syncthing tracks per-file state across folder rescans).
+type SelectionCache struct {
+	Root  string          `json:"root"`
+	Paths map[string]bool `json:"paths"`
+}
+
+func selectionCachePath(root string) (string, error) {
+	appData := os.Getenv("APPDATA")
+	if appData == "" {
+		return "", fmt.Errorf("APPDATA is not set")
+	}
+
+	dir := filepath.Join(appData, "PlexCleanup", "selections")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	sum := sha1.Sum([]byte(root))
+	return filepath.Join(dir, fmt.Sprintf("%x.json", sum)), nil
+}
+
+// LoadSelectionCache reads the cached overrides for root, returning an
+// empty cache (not an error) if there is no %APPDATA%, no cache file
+// yet, or the cache file is corrupt.
+func LoadSelectionCache(root string) *SelectionCache {
+	empty := &SelectionCache{Root: root, Paths: map[string]bool{}}
+
+	path, err := selectionCachePath(root)
+	if err != nil {
+		return empty
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return empty
+	}
+
+	var cache SelectionCache
+	if err := json.Unmarshal(data, &cache); err != nil || cache.Root != root {
+		return empty
+	}
+	if cache.Paths == nil {
+		cache.Paths = map[string]bool{}
+	}
+
+	return &cache
+}
+
+// Save writes the cache to %APPDATA%, keyed by Root.
+func (c *SelectionCache) Save() error {
+	path, err := selectionCachePath(c.Root)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,52 @@
+package main
+
+import "testing"
+
+func TestSelectionCacheSaveAndLoadRoundTrip(t *testing.T) {
+	t.Setenv("APPDATA", t.TempDir())
+
+	root := "/root/Movies"
+	cache := &SelectionCache{Root: root, Paths: map[string]bool{"/root/Movies/a.rar": true}}
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	loaded := LoadSelectionCache(root)
+	if loaded.Paths["/root/Movies/a.rar"] != true {
+		t.Errorf("expected saved override to round-trip, got %+v", loaded.Paths)
+	}
+}
+
+func TestLoadSelectionCacheMissingFileReturnsEmpty(t *testing.T) {
+	t.Setenv("APPDATA", t.TempDir())
+
+	cache := LoadSelectionCache("/never/scanned")
+	if len(cache.Paths) != 0 {
+		t.Errorf("expected empty cache for a root that was never saved, got %+v", cache.Paths)
+	}
+}
+
+func TestLoadSelectionCacheDifferentRootMismatch(t *testing.T) {
+	t.Setenv("APPDATA", t.TempDir())
+
+	a := &SelectionCache{Root: "/root/A", Paths: map[string]bool{"/root/A/x.rar": true}}
+	if err := a.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	// Different root hashes to a different cache file, so it must not
+	// see /root/A's overrides.
+	b := LoadSelectionCache("/root/B")
+	if len(b.Paths) != 0 {
+		t.Errorf("expected no overrides for an unrelated root, got %+v", b.Paths)
+	}
+}
+
+func TestLoadSelectionCacheNoAppData(t *testing.T) {
+	t.Setenv("APPDATA", "")
+
+	cache := LoadSelectionCache("/root/Movies")
+	if len(cache.Paths) != 0 {
+		t.Errorf("expected empty cache when APPDATA is unset, got %+v", cache.Paths)
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bufio"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// VerifyStatus is the outcome of checking an archive group against its
+// sibling .sfv file.
+type VerifyStatus string
+
+const (
+	VerifyUnknown    VerifyStatus = "No .sfv found"
+	VerifyVerified   VerifyStatus = "Verified"
+	VerifyIncomplete VerifyStatus = "Incomplete (missing part)"
+	VerifyMismatch   VerifyStatus = "Checksum mismatch"
+)
+
+// sfvEntry is one "filename crc32" line from an .sfv file.
+type sfvEntry struct {
+	Name string
+	CRC  uint32
+}
+
+var sfvLineRe = regexp.MustCompile(`(?i)^(.+?)\s+([0-9a-f]{8})$`)
+
+// parseSFV reads an .sfv file, skipping comments (prefixed with ";")
+// and blank lines.
+func parseSFV(path string) ([]sfvEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []sfvEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		m := sfvLineRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		crc, err := strconv.ParseUint(m[2], 16, 32)
+		if err != nil {
+			continue
+		}
+
+		entries = append(entries, sfvEntry{Name: m[1], CRC: uint32(crc)})
+	}
+
+	return entries, scanner.Err()
+}
+
+// crc32File computes the CRC-32 (IEEE) checksum of the file at path.
+func crc32File(path string) (uint32, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	h := crc32.NewIEEE()
+	if _, err := io.Copy(h, f); err != nil {
+		return 0, err
+	}
+	return h.Sum32(), nil
+}
+
+// ArchiveGroup is a set of archive parts (e.g. a .rar plus its .r01,
+// .r02, ... or a .001/.002/... sequence) that share a directory and
+// base name, along with the verification result against their sibling
+// .sfv file, if any.
+type ArchiveGroup struct {
+	Dir    string
+	Base   string
+	Files  []FileInfo
+	Status VerifyStatus
+}
+
+var archivePartExt = regexp.MustCompile(`(?i)\.(rar|r\d{2,3}|7z|zip|\d{3}|part\d+)$`)
+var archivePartSuffix = regexp.MustCompile(`(?i)(\.part\d+|\.r\d{2,3}|\.\d{3})$`)
+
+// isArchivePart reports whether name looks like a (possibly multi-part)
+// archive file rather than some other kind of unwanted file.
+func isArchivePart(name string) bool {
+	return archivePartExt.MatchString(strings.ToLower(name))
+}
+
+func archiveGroupBase(name string) string {
+	lower := strings.ToLower(name)
+	if ext := filepath.Ext(lower); ext == ".rar" || ext == ".7z" || ext == ".zip" {
+		return strings.TrimSuffix(lower, ext)
+	}
+	return archivePartSuffix.ReplaceAllString(lower, "")
+}
+
+// SplitArchiveGroups partitions files into archive-part groups (keyed
+// by directory + base name, so .rar/.r01/.001 siblings land together)
+// and files that aren't archive parts at all. A group's sibling .sfv
+// file is folded into the group too, so it shares the group's
+// verification status instead of being deleted unconditionally.
+func SplitArchiveGroups(files []FileInfo) (groups map[string]*ArchiveGroup, rest []FileInfo) {
+	groups = make(map[string]*ArchiveGroup)
+
+	var sfvFiles []FileInfo
+	for _, file := range files {
+		name := filepath.Base(file.Path)
+		if strings.EqualFold(filepath.Ext(name), ".sfv") {
+			sfvFiles = append(sfvFiles, file)
+			continue
+		}
+
+		if !isArchivePart(name) {
+			rest = append(rest, file)
+			continue
+		}
+
+		dir := filepath.Dir(file.Path)
+		base := archiveGroupBase(name)
+		key := dir + "|" + base
+
+		g, ok := groups[key]
+		if !ok {
+			g = &ArchiveGroup{Dir: dir, Base: base, Status: VerifyUnknown}
+			groups[key] = g
+		}
+		g.Files = append(g.Files, file)
+	}
+
+	for _, file := range sfvFiles {
+		name := filepath.Base(file.Path)
+		dir := filepath.Dir(file.Path)
+		base := strings.TrimSuffix(strings.ToLower(name), ".sfv")
+		key := dir + "|" + base
+
+		if g, ok := groups[key]; ok {
+			g.Files = append(g.Files, file)
+			continue
+		}
+
+		rest = append(rest, file)
+	}
+
+	return groups, rest
+}
+
+// VerifyGroup checks g's files against a sibling .sfv file in g.Dir. If
+// no .sfv file is found, Status is left as VerifyUnknown.
+func VerifyGroup(g *ArchiveGroup) {
+	sfvPath := filepath.Join(g.Dir, g.Base+".sfv")
+	entries, err := parseSFV(sfvPath)
+	if err != nil {
+		g.Status = VerifyUnknown
+		return
+	}
+
+	byName := make(map[string]FileInfo, len(g.Files))
+	for _, f := range g.Files {
+		byName[strings.ToLower(filepath.Base(f.Path))] = f
+	}
+
+	for _, entry := range entries {
+		file, ok := byName[strings.ToLower(entry.Name)]
+		if !ok {
+			g.Status = VerifyIncomplete
+			return
+		}
+
+		sum, err := crc32File(file.Path)
+		if err != nil || sum != entry.CRC {
+			g.Status = VerifyMismatch
+			return
+		}
+	}
+
+	g.Status = VerifyVerified
+}
+
+// VerifyGroups verifies every group concurrently with a bounded worker
+// pool, since each verification reads and checksums whole files.
+func VerifyGroups(groups map[string]*ArchiveGroup, workers int) {
+	if workers <= 0 {
+		workers = 4
+	}
+
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+
+	for _, g := range groups {
+		g := g
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			VerifyGroup(g)
+		}()
+	}
+
+	wg.Wait()
+}
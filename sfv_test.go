@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSplitArchiveGroups(t *testing.T) {
+	files := []FileInfo{
+		{Path: "/movies/Movie.2020.rar", Size: 100},
+		{Path: "/movies/Movie.2020.r01", Size: 100},
+		{Path: "/movies/Movie.2020.nfo", Size: 10},
+		{Path: "/movies/Other.001", Size: 50},
+	}
+
+	groups, rest := SplitArchiveGroups(files)
+
+	if len(rest) != 1 || rest[0].Path != "/movies/Movie.2020.nfo" {
+		t.Errorf("expected only the .nfo file in rest, got %v", rest)
+	}
+
+	g, ok := groups[filepath.FromSlash("/movies")+"|movie.2020"]
+	if !ok || len(g.Files) != 2 {
+		t.Fatalf("expected Movie.2020 group with 2 parts, got %+v", groups)
+	}
+
+	if _, ok := groups[filepath.FromSlash("/movies")+"|other"]; !ok {
+		t.Errorf("expected Other group for .001 part")
+	}
+}
+
+func TestSplitArchiveGroupsFoldsSiblingSFVIntoItsGroup(t *testing.T) {
+	files := []FileInfo{
+		{Path: "/movies/Movie.2020.rar", Size: 100},
+		{Path: "/movies/Movie.2020.r01", Size: 100},
+		{Path: "/movies/Movie.2020.sfv", Size: 1},
+		{Path: "/movies/Orphan.sfv", Size: 1},
+	}
+
+	groups, rest := SplitArchiveGroups(files)
+
+	g, ok := groups[filepath.FromSlash("/movies")+"|movie.2020"]
+	if !ok || len(g.Files) != 3 {
+		t.Fatalf("expected Movie.2020 group to include its .sfv sibling, got %+v", groups)
+	}
+
+	if len(rest) != 1 || rest[0].Path != "/movies/Orphan.sfv" {
+		t.Errorf("expected an .sfv file with no matching group to land in rest, got %v", rest)
+	}
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyGroupVerified(t *testing.T) {
+	dir := t.TempDir()
+	partPath := filepath.Join(dir, "Movie.2020.r01")
+	writeFile(t, partPath, []byte("archive data"))
+
+	sum := crc32.ChecksumIEEE([]byte("archive data"))
+	writeFile(t, filepath.Join(dir, "movie.2020.sfv"), []byte(fmt.Sprintf("movie.2020.r01 %08x\n", sum)))
+
+	g := &ArchiveGroup{Dir: dir, Base: "movie.2020", Files: []FileInfo{{Path: partPath, Size: 12}}}
+	VerifyGroup(g)
+
+	if g.Status != VerifyVerified {
+		t.Errorf("expected VerifyVerified, got %v", g.Status)
+	}
+}
+
+func TestVerifyGroupMismatch(t *testing.T) {
+	dir := t.TempDir()
+	partPath := filepath.Join(dir, "Movie.2020.r01")
+	writeFile(t, partPath, []byte("corrupted data"))
+
+	writeFile(t, filepath.Join(dir, "movie.2020.sfv"), []byte("movie.2020.r01 deadbeef\n"))
+
+	g := &ArchiveGroup{Dir: dir, Base: "movie.2020", Files: []FileInfo{{Path: partPath, Size: 14}}}
+	VerifyGroup(g)
+
+	if g.Status != VerifyMismatch {
+		t.Errorf("expected VerifyMismatch, got %v", g.Status)
+	}
+}
+
+func TestVerifyGroupIncomplete(t *testing.T) {
+	dir := t.TempDir()
+	partPath := filepath.Join(dir, "Movie.2020.r01")
+	writeFile(t, partPath, []byte("archive data"))
+
+	writeFile(t, filepath.Join(dir, "movie.2020.sfv"), []byte("movie.2020.r02 deadbeef\n"))
+
+	g := &ArchiveGroup{Dir: dir, Base: "movie.2020", Files: []FileInfo{{Path: partPath, Size: 12}}}
+	VerifyGroup(g)
+
+	if g.Status != VerifyIncomplete {
+		t.Errorf("expected VerifyIncomplete, got %v", g.Status)
+	}
+}
+
+func TestVerifyGroupNoSFV(t *testing.T) {
+	dir := t.TempDir()
+	partPath := filepath.Join(dir, "Movie.2020.r01")
+	writeFile(t, partPath, []byte("archive data"))
+
+	g := &ArchiveGroup{Dir: dir, Base: "movie.2020", Files: []FileInfo{{Path: partPath, Size: 12}}}
+	VerifyGroup(g)
+
+	if g.Status != VerifyUnknown {
+		t.Errorf("expected VerifyUnknown when no .sfv file exists, got %v", g.Status)
+	}
+}
+